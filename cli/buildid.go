@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Chris-Sahyouni/iago/exe"
+	"github.com/Chris-Sahyouni/iago/global"
+	"github.com/Chris-Sahyouni/iago/term"
+)
+
+// BuildId implements Command the same way Find does; it must be registered
+// under the "buildid" name wherever the REPL maps command names to
+// Command implementations (not in this file, alongside Find's own
+// registration).
+type BuildId struct{ args Args }
+
+func (BuildId) ValidArgs() bool {
+	return true
+}
+
+func (BuildId) Execute(globalState *global.GlobalState) error {
+	currFile := globalState.CurrentFile
+	if currFile == nil {
+		return errors.New("no target file specified")
+	}
+
+	elf, ok := currFile.(*exe.Elf)
+	if !ok {
+		return errors.New("buildid is only supported for ELF binaries")
+	}
+
+	buildID, err := elf.BuildID()
+	if err != nil {
+		return err
+	}
+
+	term.Println(buildID)
+	return nil
+}
+
+func (BuildId) Help() {
+	term.Println("    buildid" + strings.Repeat(" ", SPACE_BETWEEN-len("buildid")) + "Prints the GNU build-ID of the current binary, if present,")
+	term.Println(strings.Repeat(" ", SPACE_BETWEEN+4) + "so it can be correlated with symbol/debug packages on disk")
+}
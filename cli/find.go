@@ -3,8 +3,10 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/Chris-Sahyouni/iago/exe"
 	"github.com/Chris-Sahyouni/iago/global"
 	"github.com/Chris-Sahyouni/iago/term"
 )
@@ -28,16 +30,70 @@ func (f Find) Execute(globalState *global.GlobalState) error {
 		return errors.New("no target file specified")
 	}
 
+	if strings.HasPrefix(target, "@") {
+		return resolveSymbol(currFile, target)
+	}
+
 	vaddr, err := currFile.ReverseInstructionTrie().Find(target, currFile.Isa())
 	if err != nil {
 		return err
 	}
+
 	fmtString := fmt.Sprintf("virtual address: %x", vaddr)
+	if symTable, ok := currFile.(exe.SymbolTable); ok {
+		if name, offset, found := symTable.SymbolAt(vaddr); found {
+			fmtString += fmt.Sprintf(" (%s+0x%x)", name, offset)
+		}
+	}
 	term.Println(fmtString)
 	return nil
 }
 
+// resolveSymbol handles the `find @symbol+offset` mode, reporting the
+// virtual address of a named symbol (plus an optional offset) instead of
+// searching for a gadget.
+func resolveSymbol(currFile exe.Executable, target string) error {
+	symTable, ok := currFile.(exe.SymbolTable)
+	if !ok {
+		return errors.New("current file has no symbol table")
+	}
+
+	name, offset, err := parseSymbolTarget(target)
+	if err != nil {
+		return err
+	}
+
+	vaddr, ok := symTable.Symbols()[name]
+	if !ok {
+		return fmt.Errorf("symbol %q not found", name)
+	}
+
+	term.Println(fmt.Sprintf("virtual address: %x", vaddr+offset))
+	return nil
+}
+
+func parseSymbolTarget(target string) (name string, offset uint, err error) {
+	spec := strings.TrimPrefix(target, "@")
+
+	name = spec
+	if idx := strings.IndexByte(spec, '+'); idx >= 0 {
+		name = spec[:idx]
+		parsedOffset, err := strconv.ParseUint(spec[idx+1:], 0, 64)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid offset in %q: %w", target, err)
+		}
+		offset = uint(parsedOffset)
+	}
+
+	if name == "" {
+		return "", 0, fmt.Errorf("missing symbol name in %q", target)
+	}
+
+	return name, offset, nil
+}
+
 func (Find) Help() {
 	term.Println("    find <gadget>" + strings.Repeat(" ", SPACE_BETWEEN-len("find <gadget>")) + "Searches the current binary for <gadget> and returns its virtual address if found,")
 	term.Println(strings.Repeat(" ", SPACE_BETWEEN+4) + "<gadget> should be inputted as the hex representation of the machine code of <gadget>")
+	term.Println("    find @<symbol>[+<offset>]" + strings.Repeat(" ", SPACE_BETWEEN-len("find @<symbol>[+<offset>]")) + "Resolves <symbol> (optionally plus <offset>) to a virtual address")
 }
@@ -0,0 +1,126 @@
+package exe
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func zlibCompress(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(plain); err != nil {
+		t.Fatalf("zlib.Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("zlib.Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zstdCompress(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("zstd.NewWriter: %v", err)
+	}
+	return w.EncodeAll(plain, nil)
+}
+
+func TestInflateZlibRoundTrip(t *testing.T) {
+	plain := []byte("deadbeefdeadbeefdeadbeef gadget bytes go here")
+	compressed := zlibCompress(t, plain)
+
+	out, err := inflateZlib(compressed, uint64(len(plain)))
+	if err != nil {
+		t.Fatalf("inflateZlib: %v", err)
+	}
+	if !bytes.Equal(out, plain) {
+		t.Errorf("inflateZlib round-trip mismatch: got %q, want %q", out, plain)
+	}
+}
+
+func TestInflateZstdRoundTrip(t *testing.T) {
+	plain := []byte("deadbeefdeadbeefdeadbeef gadget bytes go here")
+	compressed := zstdCompress(t, plain)
+
+	out, err := inflateZstd(compressed, uint64(len(plain)))
+	if err != nil {
+		t.Fatalf("inflateZstd: %v", err)
+	}
+	if !bytes.Equal(out, plain) {
+		t.Errorf("inflateZstd round-trip mismatch: got %q, want %q", out, plain)
+	}
+}
+
+func TestInflateRejectsOversizedUncompressedSize(t *testing.T) {
+	if _, err := inflateZlib(nil, 1<<60); err == nil {
+		t.Error("inflateZlib: expected an error for an implausible uncompressed size, got nil")
+	}
+	if _, err := inflateZstd(nil, 1<<60); err == nil {
+		t.Error("inflateZstd: expected an error for an implausible uncompressed size, got nil")
+	}
+}
+
+func TestDecompressSectionShfCompressedZlib(t *testing.T) {
+	plain := []byte("some executable instruction bytes")
+	compressed := zlibCompress(t, plain)
+
+	// Elf64_Chdr: ch_type(4) ch_reserved(4) ch_size(8) ch_addralign(8)
+	chdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(chdr[0:4], elfCompressZlib)
+	binary.LittleEndian.PutUint64(chdr[8:16], uint64(len(plain)))
+
+	contents := append(chdr, compressed...)
+
+	out, err := decompressSection(contents, 0, uint(len(contents)), true, binary.LittleEndian)
+	if err != nil {
+		t.Fatalf("decompressSection: %v", err)
+	}
+	if !bytes.Equal(out, plain) {
+		t.Errorf("decompressSection round-trip mismatch: got %q, want %q", out, plain)
+	}
+}
+
+func TestDecompressSectionLegacyZlibMagic(t *testing.T) {
+	plain := []byte("legacy .zdebug section contents")
+	compressed := zlibCompress(t, plain)
+
+	// legacy format: "ZLIB" magic + 8-byte big-endian uncompressed size
+	header := make([]byte, 12)
+	copy(header[0:4], "ZLIB")
+	binary.BigEndian.PutUint64(header[4:12], uint64(len(plain)))
+
+	contents := append(header, compressed...)
+
+	if !legacyZlibCompressed(contents, 0) {
+		t.Fatal("legacyZlibCompressed: expected true for a ZLIB-prefixed section")
+	}
+
+	out, err := decompressSection(contents, 0, uint(len(contents)), true, binary.LittleEndian)
+	if err != nil {
+		t.Fatalf("decompressSection: %v", err)
+	}
+	if !bytes.Equal(out, plain) {
+		t.Errorf("decompressSection round-trip mismatch: got %q, want %q", out, plain)
+	}
+}
+
+func TestLegacyZlibCompressedNotDetectedForOrdinarySection(t *testing.T) {
+	contents := []byte{0x55, 0x48, 0x89, 0xe5, 0xc3} // push rbp; mov rbp,rsp; ret
+	if legacyZlibCompressed(contents, 0) {
+		t.Error("legacyZlibCompressed: expected false for non-ZLIB-prefixed bytes")
+	}
+}
+
+func TestLegacyZlibCompressedBoundsSafety(t *testing.T) {
+	// fewer than 4 bytes available at offset: must not panic, must report false
+	contents := []byte{'Z', 'L'}
+	if legacyZlibCompressed(contents, 0) {
+		t.Error("legacyZlibCompressed: expected false when fewer than 4 bytes remain")
+	}
+}
@@ -2,8 +2,8 @@ package exe
 
 import (
 	"encoding/binary"
-	"encoding/hex"
 	"errors"
+	"sort"
 
 	"github.com/Chris-Sahyouni/iago/isa"
 	"github.com/Chris-Sahyouni/iago/term"
@@ -16,7 +16,11 @@ type Elf struct {
 	isa                      isa.ISA
 	contents                 []byte
 	programHeaderTableOffset uint
+	sectionHeaderTableOffset uint
 	reverseInstructionTrie   *trie.TrieNode
+	symbols                  map[string]uint // name -> vaddr, populated from .symtab/.dynsym if present
+	symbolAddrs              []uint          // sorted ascending, for nearest-enclosing-symbol lookups
+	symbolsByAddr            map[uint]string
 }
 
 type elfField struct {
@@ -30,6 +34,17 @@ type segment struct {
 	VAddr  uint
 	Offset uint
 	Size   uint
+	data   []byte // set for sections that had to be decompressed; takes priority over Offset/Size when reading bytes
+}
+
+// segmentBytes returns the raw instruction bytes for a segment: its
+// already-decompressed data if it has any, otherwise the slice of contents
+// it names directly.
+func segmentBytes(contents []byte, seg segment) []byte {
+	if seg.data != nil {
+		return seg.data
+	}
+	return contents[seg.Offset : seg.Offset+seg.Size]
 }
 
 var elfHeader = map[string]elfField{
@@ -40,6 +55,9 @@ var elfHeader = map[string]elfField{
 	"program header table offset":      {0x1c, 0x20, 4, 8},
 	"program header table entry size":  {0x2a, 0x36, 2, 2},
 	"program header table num entries": {0x2c, 0x38, 2, 2},
+	"section header table offset":      {0x20, 0x28, 4, 8},
+	"section header table entry size":  {0x2e, 0x3a, 2, 2},
+	"section header table num entries": {0x30, 0x3c, 2, 2},
 }
 
 var programHeaderEntry = map[string]elfField{
@@ -51,11 +69,28 @@ var programHeaderEntry = map[string]elfField{
 	"mem size":        {0x14, 0x28, 4, 8},
 }
 
+var sectionHeaderEntry = map[string]elfField{
+	"type":   {0x04, 0x04, 4, 4},
+	"flags":  {0x08, 0x08, 4, 8},
+	"addr":   {0x0c, 0x10, 4, 8},
+	"offset": {0x10, 0x18, 4, 8},
+	"size":   {0x14, 0x20, 4, 8},
+	"link":   {0x18, 0x28, 4, 4},
+}
+
+var symtabEntry = map[string]elfField{
+	"name":  {0x00, 0x00, 4, 4},
+	"value": {0x04, 0x08, 4, 8},
+}
+
 func (e *Elf) Info() {
 	term.Println("  File Type: ELF")
 	term.Println("  Arch:", e.arch)
 	term.Println("  ISA:", e.isa.Name())
 	term.Println("  Endianness:", e.endianness)
+	if buildID, err := e.BuildID(); err == nil {
+		term.Println("  Build ID:", buildID)
+	}
 }
 
 func NewElf(elfContents []byte, args map[string]string) (Executable, error) {
@@ -86,10 +121,18 @@ func NewElf(elfContents []byte, args map[string]string) (Executable, error) {
 		return nil, err
 	}
 
-	executableSegments, err := elf.locateExecutableSegments()
+	executableSegments, err := elf.locateExecutableSections()
 	if err != nil {
 		return nil, err
 	}
+	if len(executableSegments) == 0 {
+		// no section headers (e.g. a stripped binary): fall back to
+		// scanning every executable PT_LOAD segment wholesale
+		executableSegments, err = elf.locateExecutableSegments()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	instructionStream := elf.InstructionStream(executableSegments)
 
@@ -99,6 +142,10 @@ func NewElf(elfContents []byte, args map[string]string) (Executable, error) {
 
 	elf.reverseInstructionTrie = trie.Trie(instructionStream, elf.isa)
 
+	// symbols are a convenience for `find @symbol+offset`; a binary
+	// without a symbol table can still be scanned for gadgets
+	_ = elf.parseSymbols()
+
 	return elf, nil
 }
 
@@ -269,21 +316,222 @@ func (e *Elf) locateExecutableSegments() ([]segment, error) {
 	return segments, nil
 }
 
-func (e *Elf) InstructionStream(executableSegments []segment) []isa.Instruction {
-	var instructionStream []isa.Instruction
-	instructionSize := e.isa.InstructionSize()
-	for _, segment := range executableSegments {
-		segmentContents := e.contents[segment.Offset : segment.Offset+segment.Size]
-		for i := 0; i < len(segmentContents); i += instructionSize {
-			newInstruction := isa.Instruction{
-				// make sure this is correct for big endian programs too
-				Op:    hex.EncodeToString(segmentContents[i : i+instructionSize]),
-				Vaddr: segment.VAddr + uint(i),
+const shfExecInstr = 0x4 // SHF_EXECINSTR
+
+// locateExecutableSections restricts gadget scanning to sections the
+// linker actually flagged as code (.text, .plt, .init, .fini, ...) instead
+// of every byte of every executable PT_LOAD segment, which also pulls in
+// read-only data and jump tables that pollute the trie. Returns an empty
+// slice, not an error, when the binary has no section header table at all.
+func (e *Elf) locateExecutableSections() ([]segment, error) {
+	shOffset, err := e.fieldValue("section header table offset", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+	shEntSize, err := e.fieldValue("section header table entry size", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+	shNum, err := e.fieldValue("section header table num entries", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if shNum == 0 {
+		return nil, nil
+	}
+
+	e.sectionHeaderTableOffset = shOffset
+
+	var sections []segment
+	for i := range shNum {
+		entryOffset := shOffset + (i * shEntSize)
+		flags, err := e.fieldValue("flags", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return nil, err
+		}
+
+		if flags&shfExecInstr > 0 {
+			addr, err := e.fieldValue("addr", sectionHeaderEntry, entryOffset)
+			if err != nil {
+				return nil, err
+			}
+			offset, err := e.fieldValue("offset", sectionHeaderEntry, entryOffset)
+			if err != nil {
+				return nil, err
+			}
+			size, err := e.fieldValue("size", sectionHeaderEntry, entryOffset)
+			if err != nil {
+				return nil, err
+			}
+
+			sec := segment{VAddr: addr, Offset: offset, Size: size}
+
+			// legacy .zdebug-style sections predate SHF_COMPRESSED and never
+			// set it; they're only identifiable by their "ZLIB" magic
+			// prefix, so try that on every section before trusting the flag
+			compressed := flags&shfCompressed > 0 || legacyZlibCompressed(e.contents, offset)
+			if compressed {
+				var byteOrder binary.ByteOrder = binary.LittleEndian
+				if e.endianness == "big" {
+					byteOrder = binary.BigEndian
+				}
+				decompressed, err := decompressSection(e.contents, offset, size, e.arch == 64, byteOrder)
+				if err != nil {
+					return nil, err
+				}
+				sec.data = decompressed
 			}
-			instructionStream = append(instructionStream, newInstruction)
+
+			sections = append(sections, sec)
 		}
 	}
-	return instructionStream
+
+	return sections, nil
+}
+
+const (
+	shtSymtab = 2
+	shtDynsym = 11
+)
+
+// parseSymbols walks the section header table looking for .symtab/.dynsym
+// sections, resolves each one's linked string table, and populates
+// e.symbols (name -> vaddr) plus the reverse index used by SymbolAt. It is
+// a no-op, not an error, on a binary without section headers or symbol
+// tables.
+func (e *Elf) parseSymbols() error {
+	shOffset, err := e.fieldValue("section header table offset", elfHeader, 0)
+	if err != nil {
+		return err
+	}
+	shEntSize, err := e.fieldValue("section header table entry size", elfHeader, 0)
+	if err != nil {
+		return err
+	}
+	shNum, err := e.fieldValue("section header table num entries", elfHeader, 0)
+	if err != nil {
+		return err
+	}
+	if shNum == 0 {
+		return nil
+	}
+
+	symEntrySize := uint(16)
+	if e.arch == 64 {
+		symEntrySize = 24
+	}
+
+	symbols := make(map[string]uint)
+
+	for i := range shNum {
+		entryOffset := shOffset + (i * shEntSize)
+		shType, err := e.fieldValue("type", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return err
+		}
+		if shType != shtSymtab && shType != shtDynsym {
+			continue
+		}
+
+		link, err := e.fieldValue("link", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return err
+		}
+		offset, err := e.fieldValue("offset", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return err
+		}
+		size, err := e.fieldValue("size", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return err
+		}
+
+		strtabEntryOffset := shOffset + (link * shEntSize)
+		strtabOffset, err := e.fieldValue("offset", sectionHeaderEntry, strtabEntryOffset)
+		if err != nil {
+			return err
+		}
+
+		for symOffset := offset; symOffset+symEntrySize <= offset+size; symOffset += symEntrySize {
+			nameIdx, err := e.fieldValue("name", symtabEntry, symOffset)
+			if err != nil {
+				return err
+			}
+			value, err := e.fieldValue("value", symtabEntry, symOffset)
+			if err != nil {
+				return err
+			}
+			if nameIdx == 0 || value == 0 {
+				continue
+			}
+
+			name := e.cString(strtabOffset + nameIdx)
+			if name != "" {
+				symbols[name] = value
+			}
+		}
+	}
+
+	e.symbols = symbols
+	e.buildSymbolIndex()
+
+	return nil
+}
+
+// cString reads a NUL-terminated string out of the file contents starting
+// at offset, as used by ELF string tables.
+func (e *Elf) cString(offset uint) string {
+	end := offset
+	for end < uint(len(e.contents)) && e.contents[end] != 0 {
+		end++
+	}
+	return string(e.contents[offset:end])
+}
+
+func (e *Elf) buildSymbolIndex() {
+	byAddr := make(map[uint]string, len(e.symbols))
+	for name, addr := range e.symbols {
+		// prefer a stable, deterministic name when multiple symbols alias
+		// the same address
+		if existing, ok := byAddr[addr]; !ok || name < existing {
+			byAddr[addr] = name
+		}
+	}
+
+	addrs := make([]uint, 0, len(byAddr))
+	for addr := range byAddr {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+
+	e.symbolsByAddr = byAddr
+	e.symbolAddrs = addrs
+}
+
+// Symbols returns every resolved symbol name mapped to its virtual address.
+func (e *Elf) Symbols() map[string]uint {
+	return e.symbols
+}
+
+// SymbolAt reports the nearest enclosing symbol for vaddr: the symbol with
+// the greatest address not exceeding vaddr, plus vaddr's offset into it.
+func (e *Elf) SymbolAt(vaddr uint) (name string, offset uint, ok bool) {
+	if len(e.symbolAddrs) == 0 {
+		return "", 0, false
+	}
+
+	idx := sort.Search(len(e.symbolAddrs), func(i int) bool { return e.symbolAddrs[i] > vaddr }) - 1
+	if idx < 0 {
+		return "", 0, false
+	}
+
+	addr := e.symbolAddrs[idx]
+	return e.symbolsByAddr[addr], vaddr - addr, true
+}
+
+func (e *Elf) InstructionStream(executableSegments []segment) []isa.Instruction {
+	return instructionStreamFor(e.contents, executableSegments, e.isa)
 }
 
 func (e *Elf) ReverseInstructionTrie() *trie.TrieNode {
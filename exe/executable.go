@@ -0,0 +1,28 @@
+package exe
+
+import (
+	"github.com/Chris-Sahyouni/iago/isa"
+	"github.com/Chris-Sahyouni/iago/trie"
+)
+
+// Executable is the common surface every binary format iago can load
+// exposes to the rest of the program (the cli and global packages in
+// particular), regardless of whether the underlying file is ELF, Mach-O,
+// or PE.
+type Executable interface {
+	Info()
+	ReverseInstructionTrie() *trie.TrieNode
+	Endianness() string
+	Arch() uint
+	Isa() isa.ISA
+}
+
+// SymbolTable is implemented by Executables that can carry a symbol table
+// (currently only Elf, whose Symbols map is empty or nil when the binary
+// has no symbol table). cli's find command type-asserts to
+// this to support `find @symbol+offset` and to annotate found gadgets with
+// their nearest enclosing symbol.
+type SymbolTable interface {
+	Symbols() map[string]uint
+	SymbolAt(vaddr uint) (name string, offset uint, ok bool)
+}
@@ -0,0 +1,347 @@
+package exe
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/Chris-Sahyouni/iago/isa"
+	"github.com/Chris-Sahyouni/iago/term"
+	"github.com/Chris-Sahyouni/iago/trie"
+)
+
+type MachO struct {
+	arch                   uint // either 32 or 64
+	endianness             string
+	isa                    isa.ISA
+	contents               []byte
+	reverseInstructionTrie *trie.TrieNode
+}
+
+const (
+	magic32           = 0xfeedface
+	magic64           = 0xfeedfacf
+	magic32Swapped    = 0xcefaedfe
+	magic64Swapped    = 0xcffaedfe
+	fatMagic          = 0xcafebabe
+	fatMagicSwapped   = 0xbebafeca
+	fatMagic64        = 0xcafebabf
+	fatMagic64Swapped = 0xbfbafeca
+)
+
+// load command types relevant to gadget hunting
+const (
+	lcSegment   = 0x1
+	lcSegment64 = 0x19
+)
+
+const vmProtExecute = 0x4
+
+// cpu types, as found in the mach_header cputype field
+var machoCPUToISA = map[uint32]isa.ISA{
+	0x00000007: isa.X86{},     // CPU_TYPE_X86
+	0x01000007: isa.X86{},     // CPU_TYPE_X86_64
+	0x0000000c: isa.ARM{},     // CPU_TYPE_ARM
+	0x0100000c: isa.AArch64{}, // CPU_TYPE_ARM64
+}
+
+// NewMachO parses a (non-fat) Mach-O image and builds a reverse instruction
+// trie out of its executable segments, mirroring NewElf.
+func NewMachO(contents []byte, args map[string]string) (Executable, error) {
+	if len(contents) < 4 {
+		return nil, errors.New("invalid Mach-O file: too short to contain a magic number")
+	}
+
+	byteOrder, is64, err := machoHeaderLayout(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := uint(28)
+	if is64 {
+		headerSize = 32
+	}
+	if uint(len(contents)) < headerSize {
+		return nil, errors.New("invalid Mach-O file: truncated header")
+	}
+
+	cpuType := byteOrder.Uint32(contents[4:8])
+	machoIsa, ok := machoCPUToISA[cpuType]
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	arch := uint(32)
+	endianness := "big"
+	if is64 {
+		arch = 64
+	}
+	if byteOrder == binary.LittleEndian {
+		endianness = "little"
+	}
+
+	macho := &MachO{
+		arch:       arch,
+		endianness: endianness,
+		isa:        machoIsa,
+		contents:   contents,
+	}
+
+	executableSegments, err := macho.locateExecutableSegments(byteOrder, is64, headerSize)
+	if err != nil {
+		return nil, err
+	}
+
+	instructionStream := macho.InstructionStream(executableSegments)
+	if len(instructionStream) == 0 {
+		return nil, errors.New("no executable segments found")
+	}
+
+	macho.reverseInstructionTrie = trie.Trie(instructionStream, macho.isa)
+
+	return macho, nil
+}
+
+// machoHeaderLayout reads the magic number, accounting for the byte-swapped
+// variants produced when the image's endianness doesn't match the host's,
+// and reports the byte order to use for the rest of the header plus whether
+// this is a 64-bit image.
+func machoHeaderLayout(contents []byte) (binary.ByteOrder, bool, error) {
+	magic := binary.BigEndian.Uint32(contents[0:4])
+
+	switch magic {
+	case magic32:
+		return binary.BigEndian, false, nil
+	case magic64:
+		return binary.BigEndian, true, nil
+	case magic32Swapped:
+		return binary.LittleEndian, false, nil
+	case magic64Swapped:
+		return binary.LittleEndian, true, nil
+	default:
+		return nil, false, errors.New("invalid Mach-O file: unrecognized magic number")
+	}
+}
+
+func (m *MachO) locateExecutableSegments(byteOrder binary.ByteOrder, is64 bool, headerSize uint) ([]segment, error) {
+	var segments []segment
+
+	ncmds := byteOrder.Uint32(m.contents[16:20])
+	offset := headerSize
+
+	segCmd := uint32(lcSegment)
+	if is64 {
+		segCmd = lcSegment64
+	}
+
+	for i := uint32(0); i < ncmds; i++ {
+		if uint(len(m.contents)) < offset+8 {
+			return nil, errors.New("invalid Mach-O file: load command table runs past end of file")
+		}
+		cmd := byteOrder.Uint32(m.contents[offset : offset+4])
+		cmdsize := byteOrder.Uint32(m.contents[offset+4 : offset+8])
+
+		if uint(len(m.contents)) < offset+uint(cmdsize) {
+			return nil, errors.New("invalid Mach-O file: load command runs past end of file")
+		}
+
+		if cmd == segCmd {
+			segmentCmdSize := uint(48) // segname..initprot for segment_command
+			if is64 {
+				segmentCmdSize = 64 // segname..initprot for segment_command_64
+			}
+			if uint(cmdsize) < segmentCmdSize {
+				return nil, errors.New("invalid Mach-O file: segment load command smaller than its fixed fields")
+			}
+
+			initprot, vmaddr, fileoff, filesize := machoSegmentFields(m.contents[offset:], byteOrder, is64)
+			if initprot&vmProtExecute > 0 {
+				if uint(len(m.contents)) < fileoff+filesize {
+					return nil, errors.New("invalid Mach-O file: segment runs past end of file")
+				}
+				segments = append(segments, segment{
+					VAddr:  vmaddr,
+					Offset: fileoff,
+					Size:   filesize,
+				})
+			}
+		}
+
+		offset += uint(cmdsize)
+	}
+
+	return segments, nil
+}
+
+// machoSegmentFields pulls vmaddr/fileoff/filesize/initprot out of a
+// segment_command (32-bit) or segment_command_64 load command. segname is
+// skipped since gadget discovery only cares about __TEXT's protection bits,
+// not its name.
+func machoSegmentFields(cmd []byte, byteOrder binary.ByteOrder, is64 bool) (initprot uint32, vmaddr, fileoff, filesize uint) {
+	const segnameSize = 16
+	base := 8 + segnameSize
+
+	if is64 {
+		vmaddr = uint(byteOrder.Uint64(cmd[base : base+8]))
+		fileoff = uint(byteOrder.Uint64(cmd[base+16 : base+24]))
+		filesize = uint(byteOrder.Uint64(cmd[base+24 : base+32]))
+		initprot = byteOrder.Uint32(cmd[base+36 : base+40])
+		return
+	}
+
+	vmaddr = uint(byteOrder.Uint32(cmd[base : base+4]))
+	fileoff = uint(byteOrder.Uint32(cmd[base+8 : base+12]))
+	filesize = uint(byteOrder.Uint32(cmd[base+12 : base+16]))
+	initprot = byteOrder.Uint32(cmd[base+20 : base+24])
+	return
+}
+
+// isFatMagic reports whether contents looks like a fat/universal Mach-O
+// binary rather than a thin one. 0xcafebabe is also the Java class file
+// magic number, so callers should additionally sanity-check the fat_arch
+// count (nfat_arch) before committing to this parse path.
+func isFatMagic(magic uint32) bool {
+	switch magic {
+	case fatMagic, fatMagicSwapped, fatMagic64, fatMagic64Swapped:
+		return true
+	default:
+		return false
+	}
+}
+
+type fatArch struct {
+	cpuType    uint32
+	cpuSubtype uint32
+	offset     uint
+	size       uint
+}
+
+// fatArches enumerates the embedded slices of a fat/universal Mach-O binary.
+// The fat header is always big-endian, regardless of the endianness of the
+// slices it contains. FAT_MAGIC_64 binaries use a wider fat_arch_64 entry
+// (64-bit offset/size instead of 32-bit) to address slices beyond 4GB.
+func fatArches(contents []byte) ([]fatArch, error) {
+	if len(contents) < 8 {
+		return nil, errors.New("invalid fat Mach-O file: too short to contain a fat header")
+	}
+
+	magic := binary.BigEndian.Uint32(contents[0:4])
+	is64 := magic == fatMagic64 || magic == fatMagic64Swapped
+
+	nfatArch := binary.BigEndian.Uint32(contents[4:8])
+
+	// a Java class file also starts with 0xcafebabe; its minor/major
+	// version fields live where nfat_arch does, so nfat_arch reads as at
+	// least 45 (the lowest class-file major version), far more slices than
+	// a fat binary carries
+	if nfatArch > 30 {
+		return nil, errors.New("invalid fat Mach-O file: implausible fat_arch count (possibly a Java class file)")
+	}
+
+	fatArchEntrySize := uint(20) // fat_arch: cputype, cpusubtype, offset, size, align
+	if is64 {
+		fatArchEntrySize = 32 // fat_arch_64: same fields, offset/size widened to 8 bytes, plus reserved
+	}
+	needed := 8 + uint(nfatArch)*fatArchEntrySize
+	if uint(len(contents)) < needed {
+		return nil, errors.New("invalid fat Mach-O file: fat_arch table runs past end of file")
+	}
+
+	arches := make([]fatArch, 0, nfatArch)
+	for i := uint32(0); i < nfatArch; i++ {
+		entryOffset := 8 + uint(i)*fatArchEntrySize
+		entry := contents[entryOffset : entryOffset+fatArchEntrySize]
+
+		arch := fatArch{
+			cpuType:    binary.BigEndian.Uint32(entry[0:4]),
+			cpuSubtype: binary.BigEndian.Uint32(entry[4:8]),
+		}
+		if is64 {
+			arch.offset = uint(binary.BigEndian.Uint64(entry[8:16]))
+			arch.size = uint(binary.BigEndian.Uint64(entry[16:24]))
+		} else {
+			arch.offset = uint(binary.BigEndian.Uint32(entry[8:12]))
+			arch.size = uint(binary.BigEndian.Uint32(entry[12:16]))
+		}
+		arches = append(arches, arch)
+	}
+
+	return arches, nil
+}
+
+// NewFatMachO picks the slice matching the requested architecture (via the
+// --arch flag) out of a fat/universal Mach-O binary, or, if none was
+// requested, builds one Executable per embedded slice.
+func NewFatMachO(contents []byte, args map[string]string) ([]Executable, error) {
+	arches, err := fatArches(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedArch, wantsArch := args["--arch"]
+
+	var executables []Executable
+	for _, a := range arches {
+		if uint(len(contents)) < a.offset+a.size {
+			return nil, errors.New("invalid fat Mach-O file: embedded slice runs past end of file")
+		}
+
+		if wantsArch && machoCPUTypeName(a.cpuType) != requestedArch {
+			continue
+		}
+
+		slice := contents[a.offset : a.offset+a.size]
+		exe, err := NewMachO(slice, args)
+		if err != nil {
+			continue // skip slices iago doesn't understand (e.g. unsupported ISA)
+		}
+		executables = append(executables, exe)
+	}
+
+	if len(executables) == 0 {
+		return nil, errors.New("no usable architecture slices found in fat Mach-O file")
+	}
+
+	return executables, nil
+}
+
+func machoCPUTypeName(cpuType uint32) string {
+	switch cpuType {
+	case 0x00000007:
+		return "x86"
+	case 0x01000007:
+		return "x86_64"
+	case 0x0000000c:
+		return "arm"
+	case 0x0100000c:
+		return "arm64"
+	default:
+		return "unknown"
+	}
+}
+
+func (m *MachO) Info() {
+	term.Println("  File Type: Mach-O")
+	term.Println("  Arch:", m.arch)
+	term.Println("  ISA:", m.isa.Name())
+	term.Println("  Endianness:", m.endianness)
+}
+
+func (m *MachO) InstructionStream(executableSegments []segment) []isa.Instruction {
+	return instructionStreamFor(m.contents, executableSegments, m.isa)
+}
+
+func (m *MachO) ReverseInstructionTrie() *trie.TrieNode {
+	return m.reverseInstructionTrie
+}
+
+func (m *MachO) Endianness() string {
+	return m.endianness
+}
+
+func (m *MachO) Arch() uint {
+	return m.arch
+}
+
+func (m *MachO) Isa() isa.ISA {
+	return m.isa
+}
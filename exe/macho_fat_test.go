@@ -0,0 +1,121 @@
+package exe
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func putFatHeader(magic, nfatArch uint32) []byte {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[0:4], magic)
+	binary.BigEndian.PutUint32(header[4:8], nfatArch)
+	return header
+}
+
+func put32BitArch(cpuType, cpuSubtype, offset, size uint32) []byte {
+	entry := make([]byte, 20)
+	binary.BigEndian.PutUint32(entry[0:4], cpuType)
+	binary.BigEndian.PutUint32(entry[4:8], cpuSubtype)
+	binary.BigEndian.PutUint32(entry[8:12], offset)
+	binary.BigEndian.PutUint32(entry[12:16], size)
+	return entry
+}
+
+func put64BitArch(cpuType, cpuSubtype uint32, offset, size uint64) []byte {
+	entry := make([]byte, 32)
+	binary.BigEndian.PutUint32(entry[0:4], cpuType)
+	binary.BigEndian.PutUint32(entry[4:8], cpuSubtype)
+	binary.BigEndian.PutUint64(entry[8:16], offset)
+	binary.BigEndian.PutUint64(entry[16:24], size)
+	return entry
+}
+
+func TestFatArchesTooShort(t *testing.T) {
+	_, err := fatArches([]byte{0xca, 0xfe, 0xba})
+	if err == nil {
+		t.Fatal("expected an error for a header shorter than 8 bytes, got nil")
+	}
+}
+
+func TestFatArchesImplausibleCount(t *testing.T) {
+	contents := putFatHeader(fatMagic, 1<<20)
+	_, err := fatArches(contents)
+	if err == nil {
+		t.Fatal("expected an error for an implausible fat_arch count, got nil")
+	}
+}
+
+func TestFatArchesTableRunsPastEndOfFile(t *testing.T) {
+	contents := putFatHeader(fatMagic, 2)
+	contents = append(contents, put32BitArch(0x1000007, 0x3, 0x1000, 0x2000)...)
+	// only one 32-bit entry present, but nfatArch claims two
+
+	_, err := fatArches(contents)
+	if err == nil {
+		t.Fatal("expected an error for a fat_arch table that runs past end of file, got nil")
+	}
+}
+
+func TestFatArches32Bit(t *testing.T) {
+	contents := putFatHeader(fatMagic, 2)
+	contents = append(contents, put32BitArch(0x1000007, 0x3, 0x1000, 0x2000)...) // x86_64
+	contents = append(contents, put32BitArch(0x100000c, 0x0, 0x3000, 0x4000)...) // arm64
+
+	arches, err := fatArches(contents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(arches) != 2 {
+		t.Fatalf("got %d arches, want 2", len(arches))
+	}
+
+	want := []fatArch{
+		{cpuType: 0x1000007, cpuSubtype: 0x3, offset: 0x1000, size: 0x2000},
+		{cpuType: 0x100000c, cpuSubtype: 0x0, offset: 0x3000, size: 0x4000},
+	}
+	for i, w := range want {
+		if arches[i] != w {
+			t.Errorf("arch %d = %+v, want %+v", i, arches[i], w)
+		}
+	}
+}
+
+func TestFatArches64Bit(t *testing.T) {
+	// an offset/size pair that doesn't fit in 32 bits catches a parser that
+	// mistakenly uses the 32-bit fat_arch layout for a FAT_MAGIC_64 file.
+	const wideOffset = uint64(0x1_0000_1000)
+	const wideSize = uint64(0x2000)
+
+	contents := putFatHeader(fatMagic64, 1)
+	contents = append(contents, put64BitArch(0x1000007, 0x3, wideOffset, wideSize)...)
+
+	arches, err := fatArches(contents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(arches) != 1 {
+		t.Fatalf("got %d arches, want 1", len(arches))
+	}
+
+	got := arches[0]
+	want := fatArch{cpuType: 0x1000007, cpuSubtype: 0x3, offset: uint(wideOffset), size: uint(wideSize)}
+	if got != want {
+		t.Errorf("arch = %+v, want %+v", got, want)
+	}
+}
+
+func TestFatArches64BitSwappedMagic(t *testing.T) {
+	contents := putFatHeader(fatMagic64Swapped, 1)
+	contents = append(contents, put64BitArch(0x1000007, 0x3, 0x5000, 0x6000)...)
+
+	arches, err := fatArches(contents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(arches) != 1 {
+		t.Fatalf("got %d arches, want 1", len(arches))
+	}
+	if arches[0].offset != 0x5000 || arches[0].size != 0x6000 {
+		t.Errorf("arch = %+v, want offset 0x5000 size 0x6000", arches[0])
+	}
+}
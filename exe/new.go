@@ -0,0 +1,54 @@
+package exe
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var elfMagic = [4]byte{0x7f, 'E', 'L', 'F'}
+var mzMagic = [2]byte{'M', 'Z'}
+
+// New inspects the magic number at the start of contents and dispatches to
+// the right format-specific parser, so that callers (cli's load command)
+// don't need to know ahead of time whether they were handed an ELF, PE,
+// Mach-O, or fat/universal Mach-O binary. It always returns at least one
+// Executable; fat Mach-O inputs can yield one per embedded architecture
+// slice.
+func New(contents []byte, args map[string]string) ([]Executable, error) {
+	if len(contents) < 4 {
+		return nil, errors.New("invalid executable: file too short to contain a magic number")
+	}
+
+	if [4]byte(contents[0:4]) == elfMagic {
+		e, err := NewElf(contents, args)
+		if err != nil {
+			return nil, err
+		}
+		return []Executable{e}, nil
+	}
+
+	if [2]byte(contents[0:2]) == mzMagic {
+		p, err := NewPE(contents, args)
+		if err != nil {
+			return nil, err
+		}
+		return []Executable{p}, nil
+	}
+
+	magic := binary.BigEndian.Uint32(contents[0:4])
+
+	if isFatMagic(magic) {
+		return NewFatMachO(contents, args)
+	}
+
+	switch magic {
+	case magic32, magic64, magic32Swapped, magic64Swapped:
+		m, err := NewMachO(contents, args)
+		if err != nil {
+			return nil, err
+		}
+		return []Executable{m}, nil
+	}
+
+	return nil, errors.New("unrecognized executable format")
+}
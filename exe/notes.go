@@ -0,0 +1,205 @@
+package exe
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+// Note is a decoded ELF note record, as found in PT_NOTE segments and
+// SHT_NOTE sections.
+type Note struct {
+	Name string
+	Type uint32
+	Desc []byte
+}
+
+const (
+	ptNote  = 4 // PT_NOTE
+	shtNote = 7 // SHT_NOTE
+)
+
+// maxNoteDescSize guards against a malformed or hostile descsz field
+// causing an enormous allocation.
+const maxNoteDescSize = 1 << 20 // 1 MiB
+
+type byteRange struct {
+	offset uint
+	size   uint
+}
+
+// Notes walks every PT_NOTE segment and SHT_NOTE section and decodes their
+// note records.
+func (e *Elf) Notes() ([]Note, error) {
+	ranges, err := e.noteRanges()
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []Note
+	for _, r := range ranges {
+		parsed, err := e.parseNotes(r.offset, r.size)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, parsed...)
+	}
+
+	return notes, nil
+}
+
+// noteRanges locates the raw byte ranges containing note records, from
+// both the program header table (PT_NOTE) and, if present, the section
+// header table (SHT_NOTE) - relocatable objects in particular may carry
+// notes only as sections.
+func (e *Elf) noteRanges() ([]byteRange, error) {
+	var ranges []byteRange
+
+	programHeaderTableOffset, err := e.fieldValue("program header table offset", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+	programHeaderTableEntrySize, err := e.fieldValue("program header table entry size", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+	numEntries, err := e.fieldValue("program header table num entries", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range numEntries {
+		entryOffset := programHeaderTableOffset + (i * programHeaderTableEntrySize)
+		segType, err := e.fieldValue("segment type", programHeaderEntry, entryOffset)
+		if err != nil {
+			return nil, err
+		}
+		if segType != ptNote {
+			continue
+		}
+
+		offset, err := e.fieldValue("segment offset", programHeaderEntry, entryOffset)
+		if err != nil {
+			return nil, err
+		}
+		size, err := e.fieldValue("file size", programHeaderEntry, entryOffset)
+		if err != nil {
+			return nil, err
+		}
+
+		ranges = append(ranges, byteRange{offset: offset, size: size})
+	}
+
+	shOffset, err := e.fieldValue("section header table offset", elfHeader, 0)
+	if err != nil {
+		return ranges, nil // no section header table; PT_NOTE ranges (if any) are all we have
+	}
+	shEntSize, err := e.fieldValue("section header table entry size", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+	shNum, err := e.fieldValue("section header table num entries", elfHeader, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range shNum {
+		entryOffset := shOffset + (i * shEntSize)
+		shType, err := e.fieldValue("type", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return nil, err
+		}
+		if shType != shtNote {
+			continue
+		}
+
+		offset, err := e.fieldValue("offset", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return nil, err
+		}
+		size, err := e.fieldValue("size", sectionHeaderEntry, entryOffset)
+		if err != nil {
+			return nil, err
+		}
+
+		ranges = append(ranges, byteRange{offset: offset, size: size})
+	}
+
+	return ranges, nil
+}
+
+// parseNotes decodes the note records packed into contents[offset:offset+size]:
+// a namesz/descsz/type header, then the name and descriptor, each padded
+// out to 4-byte alignment.
+func (e *Elf) parseNotes(offset, size uint) ([]Note, error) {
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	if e.endianness == "big" {
+		byteOrder = binary.BigEndian
+	}
+
+	var notes []Note
+	pos := offset
+	end := offset + size
+
+	for pos+12 <= end {
+		if uint(len(e.contents)) < pos+12 {
+			return nil, errors.New("invalid ELF file: note header runs past end of file")
+		}
+
+		namesz := byteOrder.Uint32(e.contents[pos : pos+4])
+		descsz := byteOrder.Uint32(e.contents[pos+4 : pos+8])
+		noteType := byteOrder.Uint32(e.contents[pos+8 : pos+12])
+		pos += 12
+
+		if uint64(descsz) > maxNoteDescSize {
+			return nil, errors.New("invalid ELF file: note descriptor exceeds size limit")
+		}
+
+		nameEnd := pos + uint(namesz)
+		if uint(len(e.contents)) < nameEnd {
+			return nil, errors.New("invalid ELF file: note name runs past end of file")
+		}
+		var name string
+		if namesz > 0 {
+			name = string(e.contents[pos : nameEnd-1]) // drop the NUL terminator
+		}
+		pos = align4(nameEnd)
+
+		descEnd := pos + uint(descsz)
+		if uint(len(e.contents)) < descEnd {
+			return nil, errors.New("invalid ELF file: note descriptor runs past end of file")
+		}
+		desc := e.contents[pos:descEnd]
+		pos = align4(descEnd)
+
+		notes = append(notes, Note{Name: name, Type: noteType, Desc: desc})
+	}
+
+	return notes, nil
+}
+
+func align4(x uint) uint {
+	return (x + 3) &^ 3
+}
+
+const (
+	gnuNoteName  = "GNU"
+	ntGnuBuildID = 3
+)
+
+// BuildID returns the hex-encoded GNU build-ID descriptor, used to
+// correlate a loaded binary with symbol/debug packages on disk.
+func (e *Elf) BuildID() (string, error) {
+	notes, err := e.Notes()
+	if err != nil {
+		return "", err
+	}
+
+	for _, n := range notes {
+		if n.Name == gnuNoteName && n.Type == ntGnuBuildID {
+			return hex.EncodeToString(n.Desc), nil
+		}
+	}
+
+	return "", errors.New("no GNU build-ID note found")
+}
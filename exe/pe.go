@@ -0,0 +1,180 @@
+package exe
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/Chris-Sahyouni/iago/isa"
+	"github.com/Chris-Sahyouni/iago/term"
+	"github.com/Chris-Sahyouni/iago/trie"
+)
+
+type PE struct {
+	arch                   uint // either 32 or 64
+	endianness             string
+	isa                    isa.ISA
+	contents               []byte
+	reverseInstructionTrie *trie.TrieNode
+}
+
+const (
+	peMachineX86     = 0x14c
+	peMachineX86_64  = 0x8664
+	peMachineARM     = 0x1c0
+	peMachineThumb   = 0x1c4
+	peMachineAArch64 = 0xaa64
+)
+
+var peMachineToISA = map[uint16]isa.ISA{
+	peMachineX86:     isa.X86{},
+	peMachineX86_64:  isa.X86{},
+	peMachineARM:     isa.ARM{},
+	peMachineThumb:   isa.Thumb{},
+	peMachineAArch64: isa.AArch64{},
+}
+
+const sectionMemExecute = 0x20000000
+
+const peOptHeaderMagicPE32 = 0x10b
+const peOptHeaderMagicPE32Plus = 0x20b
+
+// NewPE parses a PE/COFF image (.exe/.dll) and builds a reverse
+// instruction trie out of its executable sections, mirroring NewElf.
+// PE/COFF is always little-endian.
+func NewPE(contents []byte, args map[string]string) (Executable, error) {
+	if len(contents) < 0x40 {
+		return nil, errors.New("invalid PE file: too short to contain an MZ stub")
+	}
+
+	peHeaderOffset := uint(binary.LittleEndian.Uint32(contents[0x3c:0x40]))
+	if uint(len(contents)) < peHeaderOffset+24 {
+		return nil, errors.New("invalid PE file: PE header offset outside file bounds")
+	}
+
+	signature := contents[peHeaderOffset : peHeaderOffset+4]
+	if signature[0] != 'P' || signature[1] != 'E' || signature[2] != 0 || signature[3] != 0 {
+		return nil, errors.New("invalid PE file: missing PE\\0\\0 signature")
+	}
+
+	fileHeaderOffset := peHeaderOffset + 4
+	machine := binary.LittleEndian.Uint16(contents[fileHeaderOffset : fileHeaderOffset+2])
+	peIsa, ok := peMachineToISA[machine]
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	numberOfSections := binary.LittleEndian.Uint16(contents[fileHeaderOffset+2 : fileHeaderOffset+4])
+	sizeOfOptionalHeader := binary.LittleEndian.Uint16(contents[fileHeaderOffset+16 : fileHeaderOffset+18])
+
+	optionalHeaderOffset := fileHeaderOffset + 20
+	if uint(len(contents)) < optionalHeaderOffset+2 {
+		return nil, errors.New("invalid PE file: optional header outside file bounds")
+	}
+
+	optMagic := binary.LittleEndian.Uint16(contents[optionalHeaderOffset : optionalHeaderOffset+2])
+
+	var imageBase uint
+	switch optMagic {
+	case peOptHeaderMagicPE32:
+		imageBase = uint(binary.LittleEndian.Uint32(contents[optionalHeaderOffset+28 : optionalHeaderOffset+32]))
+	case peOptHeaderMagicPE32Plus:
+		imageBase = uint(binary.LittleEndian.Uint64(contents[optionalHeaderOffset+24 : optionalHeaderOffset+32]))
+	default:
+		return nil, errors.New("invalid PE file: unrecognized optional header magic")
+	}
+
+	arch := uint(32)
+	if optMagic == peOptHeaderMagicPE32Plus {
+		arch = 64
+	}
+
+	pe := &PE{
+		arch:       arch,
+		endianness: "little",
+		isa:        peIsa,
+		contents:   contents,
+	}
+
+	sectionTableOffset := optionalHeaderOffset + uint(sizeOfOptionalHeader)
+	executableSections, err := pe.locateExecutableSections(sectionTableOffset, numberOfSections, imageBase)
+	if err != nil {
+		return nil, err
+	}
+
+	instructionStream := pe.InstructionStream(executableSections)
+	if len(instructionStream) == 0 {
+		return nil, errors.New("no executable sections found")
+	}
+
+	pe.reverseInstructionTrie = trie.Trie(instructionStream, pe.isa)
+
+	return pe, nil
+}
+
+const peSectionEntrySize = 40
+
+func (p *PE) locateExecutableSections(sectionTableOffset uint, numberOfSections uint16, imageBase uint) ([]segment, error) {
+	var sections []segment
+
+	for i := uint16(0); i < numberOfSections; i++ {
+		entryOffset := sectionTableOffset + uint(i)*peSectionEntrySize
+		if uint(len(p.contents)) < entryOffset+peSectionEntrySize {
+			return nil, errors.New("invalid PE file: section table runs past end of file")
+		}
+		entry := p.contents[entryOffset : entryOffset+peSectionEntrySize]
+
+		virtualSize := uint(binary.LittleEndian.Uint32(entry[8:12]))
+		virtualAddress := uint(binary.LittleEndian.Uint32(entry[12:16]))
+		sizeOfRawData := uint(binary.LittleEndian.Uint32(entry[16:20]))
+		pointerToRawData := uint(binary.LittleEndian.Uint32(entry[20:24]))
+		characteristics := binary.LittleEndian.Uint32(entry[36:40])
+
+		if characteristics&sectionMemExecute == 0 {
+			continue
+		}
+
+		size := sizeOfRawData
+		if virtualSize < size {
+			size = virtualSize
+		}
+
+		if uint(len(p.contents)) < pointerToRawData+size {
+			return nil, errors.New("invalid PE file: section data runs past end of file")
+		}
+
+		sections = append(sections, segment{
+			VAddr:  imageBase + virtualAddress,
+			Offset: pointerToRawData,
+			Size:   size,
+		})
+	}
+
+	return sections, nil
+}
+
+func (p *PE) Info() {
+	term.Println("  File Type: PE")
+	term.Println("  Arch:", p.arch)
+	term.Println("  ISA:", p.isa.Name())
+	term.Println("  Endianness:", p.endianness)
+}
+
+func (p *PE) InstructionStream(executableSections []segment) []isa.Instruction {
+	return instructionStreamFor(p.contents, executableSections, p.isa)
+}
+
+func (p *PE) ReverseInstructionTrie() *trie.TrieNode {
+	return p.reverseInstructionTrie
+}
+
+func (p *PE) Endianness() string {
+	return p.endianness
+}
+
+func (p *PE) Arch() uint {
+	return p.arch
+}
+
+func (p *PE) Isa() isa.ISA {
+	return p.isa
+}
@@ -0,0 +1,148 @@
+package exe
+
+import (
+	"encoding/hex"
+
+	"github.com/Chris-Sahyouni/iago/isa"
+)
+
+// variableLengthDecoder is implemented by ISAs whose instructions aren't a
+// fixed number of bytes (currently just isa.X86), so InstructionStream can
+// tell when it needs to fall back to backward gadget scanning instead of a
+// fixed-stride slice.
+type variableLengthDecoder interface {
+	Decode(bytes []byte, vaddr uint) (op string, length int, err error)
+}
+
+// maxGadgetBytes bounds how far back from a terminator instruction this
+// scans looking for a valid decode; maxGadgetInstructions additionally
+// bounds how many instructions a single gadget may contain. ROP gadgets
+// longer than this aren't useful in practice and bounding the search keeps
+// it from being quadratic in segment size.
+const (
+	maxGadgetBytes        = 24
+	maxGadgetInstructions = 6
+)
+
+// instructionStreamFor builds the instruction stream for segments against the
+// given ISA, shared by Elf, PE, and MachO. x86/x86_64 instructions aren't
+// fixed-width, so a fixed-stride slice (below) would cut instructions in
+// the wrong place; when the ISA can decode its own instruction lengths,
+// enumerate gadgets by scanning backward from return/jump/call terminators
+// instead.
+func instructionStreamFor(contents []byte, segments []segment, i isa.ISA) []isa.Instruction {
+	if dec, ok := i.(variableLengthDecoder); ok {
+		var stream []isa.Instruction
+		for _, seg := range segments {
+			stream = append(stream, gadgetsInSegment(contents, seg, dec)...)
+		}
+		return stream
+	}
+
+	var stream []isa.Instruction
+	instructionSize := i.InstructionSize()
+	for _, seg := range segments {
+		segmentContents := segmentBytes(contents, seg)
+		for pos := 0; pos+instructionSize <= len(segmentContents); pos += instructionSize {
+			stream = append(stream, isa.Instruction{
+				Op:    hex.EncodeToString(segmentContents[pos : pos+instructionSize]),
+				Vaddr: seg.VAddr + uint(pos),
+			})
+		}
+	}
+	return stream
+}
+
+// gadgetsInSegment finds every ret/jmp/call terminator in segment and,
+// for each one, every byte offset behind it from which the bytes decode
+// cleanly, instruction by instruction, all the way to the terminator's end.
+// Each such offset is a usable ROP gadget and is inserted into the stream
+// keyed by the hex of its full (possibly multi-instruction) byte sequence.
+func gadgetsInSegment(contents []byte, seg segment, dec variableLengthDecoder) []isa.Instruction {
+	var stream []isa.Instruction
+
+	segmentContents := segmentBytes(contents, seg)
+
+	for i := 0; i < len(segmentContents); i++ {
+		termLen, ok := x86TerminatorAt(segmentContents, i, seg.VAddr, dec)
+		if !ok {
+			continue
+		}
+		termEnd := i + termLen
+
+		earliestStart := i - maxGadgetBytes
+		if earliestStart < 0 {
+			earliestStart = 0
+		}
+
+		for start := i; start >= earliestStart; start-- {
+			if !decodesCleanly(segmentContents, start, termEnd, seg.VAddr, dec) {
+				continue
+			}
+			stream = append(stream, isa.Instruction{
+				Op:    hex.EncodeToString(segmentContents[start:termEnd]),
+				Vaddr: seg.VAddr + uint(start),
+			})
+		}
+	}
+
+	return stream
+}
+
+// x86TerminatorAt reports the length of the ret/jmp/call instruction
+// starting at segmentContents[i], if there is one: C3 (ret), C2 iw
+// (ret imm16), CB (retf), CA iw (retf imm16), or FF /2 through FF /5
+// (indirect call/jmp, whose length depends on the addressing mode so it's
+// resolved via dec.Decode).
+func x86TerminatorAt(segmentContents []byte, i int, vaddrBase uint, dec variableLengthDecoder) (length int, ok bool) {
+	if i >= len(segmentContents) {
+		return 0, false
+	}
+
+	switch segmentContents[i] {
+	case 0xc3, 0xcb:
+		return 1, true
+	case 0xc2, 0xca:
+		if i+3 > len(segmentContents) {
+			return 0, false
+		}
+		return 3, true
+	case 0xff:
+		if i+2 > len(segmentContents) {
+			return 0, false
+		}
+		modrmReg := (segmentContents[i+1] >> 3) & 0x7
+		if modrmReg < 2 || modrmReg > 5 { // /2, /3 = near/far call; /4, /5 = near/far jmp
+			return 0, false
+		}
+		_, length, err := dec.Decode(segmentContents[i:], vaddrBase+uint(i))
+		if err != nil {
+			return 0, false
+		}
+		return length, true
+	default:
+		return 0, false
+	}
+}
+
+// decodesCleanly reports whether the bytes from start to end decode, one
+// instruction after another with no gaps or overlaps, in maxGadgetInstructions
+// or fewer instructions.
+func decodesCleanly(segmentContents []byte, start, end int, vaddrBase uint, dec variableLengthDecoder) bool {
+	pos := start
+	for count := 0; count < maxGadgetInstructions; count++ {
+		if pos == end {
+			return true
+		}
+		if pos > end {
+			return false
+		}
+
+		_, length, err := dec.Decode(segmentContents[pos:], vaddrBase+uint(pos))
+		if err != nil || length <= 0 {
+			return false
+		}
+		pos += length
+	}
+	return pos == end
+}
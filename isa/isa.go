@@ -0,0 +1,31 @@
+package isa
+
+// ISA abstracts over the instruction set a loaded binary targets, so exe
+// can decode instruction streams and print identifying info without
+// caring which concrete architecture it's looking at.
+type ISA interface {
+	Name() string
+	InstructionSize() int
+}
+
+// Instruction is a decoded instruction (or, for x86 gadget search, the hex
+// of a full multi-instruction sequence) at a known virtual address.
+type Instruction struct {
+	Op    string
+	Vaddr uint
+}
+
+type ARM struct{}
+
+func (ARM) Name() string         { return "ARM" }
+func (ARM) InstructionSize() int { return 4 }
+
+type AArch64 struct{}
+
+func (AArch64) Name() string         { return "AArch64" }
+func (AArch64) InstructionSize() int { return 4 }
+
+type Thumb struct{}
+
+func (Thumb) Name() string         { return "Thumb" }
+func (Thumb) InstructionSize() int { return 2 }
@@ -0,0 +1,245 @@
+package isa
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// X86 covers both the 32- and 64-bit variants of the ISA; exe's ELF/PE/
+// Mach-O header tables map either machine value to this same type. Unlike
+// the other ISAs in this package its instructions aren't fixed-width, so
+// it implements Decode to size instructions for exe's backward gadget
+// scan instead of relying on InstructionSize.
+type X86 struct{}
+
+func (X86) Name() string { return "x86" }
+
+// InstructionSize isn't meaningful for x86; callers that can type-assert
+// for Decode should use that instead.
+func (X86) InstructionSize() int { return 1 }
+
+// x86OpcodeInfo describes how to size the rest of an instruction once its
+// primary opcode byte is known.
+type x86OpcodeInfo struct {
+	hasModRM bool
+	immSize  int // immediate size in bytes; -1 means operand-size-dependent (4, or 2 with the 0x66 prefix)
+}
+
+// legacy prefix bytes that may precede the opcode, in any order/repetition
+var x86LegacyPrefixes = map[byte]bool{
+	0xf0: true, 0xf2: true, 0xf3: true,
+	0x2e: true, 0x36: true, 0x3e: true, 0x26: true, 0x64: true, 0x65: true,
+	0x66: true, 0x67: true,
+}
+
+// x86OneByteOpcodes classifies the one-byte opcode map far enough to size
+// gadget-length instructions; anything not listed is assumed to take no
+// ModR/M byte and no immediate, which covers the single-byte push/pop/
+// inc/dec/stack/flag instructions this table omits for brevity.
+var x86OneByteOpcodes = map[byte]x86OpcodeInfo{
+	0x00: {true, 0}, 0x01: {true, 0}, 0x02: {true, 0}, 0x03: {true, 0},
+	0x04: {false, 1}, 0x05: {false, -1},
+	0x08: {true, 0}, 0x09: {true, 0}, 0x0a: {true, 0}, 0x0b: {true, 0},
+	0x0c: {false, 1}, 0x0d: {false, -1},
+	0x10: {true, 0}, 0x11: {true, 0}, 0x12: {true, 0}, 0x13: {true, 0},
+	0x14: {false, 1}, 0x15: {false, -1},
+	0x18: {true, 0}, 0x19: {true, 0}, 0x1a: {true, 0}, 0x1b: {true, 0},
+	0x1c: {false, 1}, 0x1d: {false, -1},
+	0x20: {true, 0}, 0x21: {true, 0}, 0x22: {true, 0}, 0x23: {true, 0},
+	0x24: {false, 1}, 0x25: {false, -1},
+	0x28: {true, 0}, 0x29: {true, 0}, 0x2a: {true, 0}, 0x2b: {true, 0},
+	0x2c: {false, 1}, 0x2d: {false, -1},
+	0x30: {true, 0}, 0x31: {true, 0}, 0x32: {true, 0}, 0x33: {true, 0},
+	0x34: {false, 1}, 0x35: {false, -1},
+	0x38: {true, 0}, 0x39: {true, 0}, 0x3a: {true, 0}, 0x3b: {true, 0},
+	0x3c: {false, 1}, 0x3d: {false, -1},
+	0x68: {false, -1}, 0x69: {true, -1},
+	0x6a: {false, 1}, 0x6b: {true, 1},
+	0x70: {false, 1}, 0x71: {false, 1}, 0x72: {false, 1}, 0x73: {false, 1},
+	0x74: {false, 1}, 0x75: {false, 1}, 0x76: {false, 1}, 0x77: {false, 1},
+	0x78: {false, 1}, 0x79: {false, 1}, 0x7a: {false, 1}, 0x7b: {false, 1},
+	0x7c: {false, 1}, 0x7d: {false, 1}, 0x7e: {false, 1}, 0x7f: {false, 1},
+	0x80: {true, 1}, 0x81: {true, -1}, 0x82: {true, 1}, 0x83: {true, 1},
+	0x84: {true, 0}, 0x85: {true, 0}, 0x86: {true, 0}, 0x87: {true, 0},
+	0x88: {true, 0}, 0x89: {true, 0}, 0x8a: {true, 0}, 0x8b: {true, 0},
+	0x8d: {true, 0}, 0x8f: {true, 0},
+	0xa8: {false, 1}, 0xa9: {false, -1},
+	0xb0: {false, 1}, 0xb1: {false, 1}, 0xb2: {false, 1}, 0xb3: {false, 1},
+	0xb4: {false, 1}, 0xb5: {false, 1}, 0xb6: {false, 1}, 0xb7: {false, 1},
+	0xc0: {true, 1}, 0xc1: {true, 1},
+	0xc2: {false, 2}, 0xc6: {true, 1}, 0xc7: {true, -1},
+	0xca: {false, 2},
+	0xd0: {true, 0}, 0xd1: {true, 0}, 0xd2: {true, 0}, 0xd3: {true, 0},
+	0xe8: {false, 4}, 0xe9: {false, 4}, 0xeb: {false, 1},
+	0xf6: {true, 0}, 0xf7: {true, 0}, // group 3: TEST takes an immediate, NOT/NEG/MUL/IMUL/DIV/IDIV don't; resolved after the ModR/M byte
+	0xfe: {true, 0}, 0xff: {true, 0},
+}
+
+// x86TwoByteOpcodes covers the 0F xx map; opcodes not listed here (and not
+// one of the 0F 80-0F 8F Jcc rel32 forms, handled separately) default to
+// ModR/M with no immediate, which is correct for the bulk of the SSE/MMX
+// move and compare instructions this table omits.
+var x86TwoByteOpcodes = map[byte]x86OpcodeInfo{
+	0x05: {false, 0},                                                   // syscall
+	0x0b: {false, 0},                                                   // ud2
+	0xa2: {false, 0},                                                   // cpuid
+	0xa3: {true, 0}, 0xab: {true, 0}, 0xb3: {true, 0}, 0xbb: {true, 0}, // bt/bts/btr/btc
+	0xa4: {true, 1}, 0xac: {true, 1}, // shld/shrd r/m, r, imm8
+	0xaf: {true, 0},                  // imul
+	0xb0: {true, 0}, 0xb1: {true, 0}, // cmpxchg
+	0xb6: {true, 0}, 0xb7: {true, 0}, 0xbe: {true, 0}, 0xbf: {true, 0}, // movzx/movsx
+	0xba: {true, 1},                  // group 8: bt/bts/btr/btc r/m, imm8
+	0xc0: {true, 0}, 0xc1: {true, 0}, // xadd
+}
+
+// decodeModRM returns the number of bytes occupied by the ModR/M byte at
+// b[pos] plus any SIB byte and displacement it implies (32-bit/64-bit
+// addressing only; 16-bit addressing isn't handled, as it's vanishingly
+// rare in binaries worth scanning for gadgets).
+func decodeModRM(b []byte, pos int) (int, error) {
+	if pos >= len(b) {
+		return 0, errors.New("isa: truncated ModR/M byte")
+	}
+	modrm := b[pos]
+	mod := modrm >> 6
+	rm := modrm & 0x7
+	length := 1
+
+	if mod == 3 {
+		return length, nil // register operand: no SIB, no displacement
+	}
+
+	if rm == 4 { // SIB byte present
+		if pos+length >= len(b) {
+			return 0, errors.New("isa: truncated SIB byte")
+		}
+		sib := b[pos+length]
+		length++
+		if mod == 0 && sib&0x7 == 5 {
+			length += 4 // disp32 replaces base
+		}
+	}
+
+	switch {
+	case mod == 0 && rm == 5:
+		length += 4 // disp32 / RIP-relative
+	case mod == 1:
+		length++
+	case mod == 2:
+		length += 4
+	}
+
+	return length, nil
+}
+
+// Decode implements the variableLengthDecoder interface exe's gadget scan
+// relies on (see exe/x86gadgets.go): it walks legacy/REX prefixes, sizes
+// the opcode (including the 0F/0F38/0F3A escape maps), and adds whatever
+// ModR/M+SIB+displacement and immediate bytes that opcode takes.
+func (X86) Decode(b []byte, vaddr uint) (op string, length int, err error) {
+	pos := 0
+
+	var operandSizeOverride, rexW bool
+
+	for count := 0; pos < len(b) && count < 4 && x86LegacyPrefixes[b[pos]]; count++ {
+		if b[pos] == 0x66 {
+			operandSizeOverride = true
+		}
+		pos++
+	}
+
+	if pos < len(b) && b[pos] >= 0x40 && b[pos] <= 0x4f {
+		rexW = b[pos]&0x08 != 0
+		pos++
+	}
+
+	if pos >= len(b) {
+		return "", 0, errors.New("isa: truncated x86 instruction")
+	}
+	opcode := b[pos]
+	pos++
+
+	var info x86OpcodeInfo
+
+	if opcode == 0x0f {
+		if pos >= len(b) {
+			return "", 0, errors.New("isa: truncated x86 instruction")
+		}
+		second := b[pos]
+		pos++
+
+		switch {
+		case second == 0x38, second == 0x3a:
+			if pos >= len(b) {
+				return "", 0, errors.New("isa: truncated x86 instruction")
+			}
+			pos++ // the actual 0F 38/3A xx opcode byte
+			info = x86OpcodeInfo{hasModRM: true}
+			if second == 0x3a {
+				info.immSize = 1
+			}
+		case second >= 0x80 && second <= 0x8f: // Jcc rel32
+			pos += 4
+			if pos > len(b) {
+				return "", 0, errors.New("isa: truncated x86 instruction")
+			}
+			return hex.EncodeToString(b[:pos]), pos, nil
+		default:
+			var ok bool
+			info, ok = x86TwoByteOpcodes[second]
+			if !ok {
+				info = x86OpcodeInfo{hasModRM: true}
+			}
+		}
+	} else if opcode >= 0xb8 && opcode <= 0xbf { // mov r, imm: imm64 with REX.W, else imm16 with the 0x66 prefix, else imm32
+		size := 4
+		if rexW {
+			size = 8
+		} else if operandSizeOverride {
+			size = 2
+		}
+		pos += size
+		if pos > len(b) {
+			return "", 0, errors.New("isa: truncated x86 instruction")
+		}
+		return hex.EncodeToString(b[:pos]), pos, nil
+	} else {
+		info = x86OneByteOpcodes[opcode]
+	}
+
+	if info.hasModRM {
+		modrmLen, err := decodeModRM(b, pos)
+		if err != nil {
+			return "", 0, err
+		}
+		modrmByte := b[pos]
+
+		if opcode == 0xf6 || opcode == 0xf7 {
+			reg := (modrmByte >> 3) & 0x7
+			if reg == 0 || reg == 1 { // TEST r/m, imm
+				if opcode == 0xf6 {
+					info.immSize = 1
+				} else {
+					info.immSize = -1
+				}
+			}
+		}
+
+		pos += modrmLen
+	}
+
+	immSize := info.immSize
+	if immSize == -1 {
+		immSize = 4
+		if operandSizeOverride {
+			immSize = 2
+		}
+	}
+	pos += immSize
+
+	if pos > len(b) {
+		return "", 0, errors.New("isa: truncated x86 instruction")
+	}
+
+	return hex.EncodeToString(b[:pos]), pos, nil
+}
@@ -0,0 +1,75 @@
+package isa
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestX86DecodeLength(t *testing.T) {
+	tests := []struct {
+		name       string
+		bytes      string // hex-encoded instruction bytes, possibly with trailing junk
+		wantLength int
+	}{
+		{"nop (unlisted opcode, no ModR/M, no immediate)", "90", 1},
+		{"add r/m32, r32 (ModR/M, register operand)", "0103", 2},
+		{"add eax, imm32", "0578563412", 5},
+		{"mov r/m32, imm32 with disp32 ModR/M", "c7059099887766554433221199", 10},
+		{"push imm32", "6878563412", 5},
+		{"jcc rel8", "7405", 2},
+		{"call rel32", "e878563412", 5},
+		{"mov r64, imm64 (REX.W + 0xb8-0xbf)", "48bb0011223344556677", 10},
+		{"mov r32, imm32 (no REX.W)", "bb00112233", 5},
+		{"mov r16, imm16 (0x66 prefix)", "66bb0011", 4},
+		{"syscall (0F 05, no ModR/M)", "0f05", 2},
+		{"ud2 (0F 0B, no ModR/M)", "0f0b", 2},
+		{"movzx r32, r/m8 (0F B6 + ModR/M)", "0fb6c0", 3},
+		{"jcc rel32 (0F 8x)", "0f8478563412", 6},
+		{"bt r/m32, imm8 (0F BA group 8 + ModR/M + imm8)", "0fbac005", 4},
+		{"test r/m32, imm32 (0xF7 /0)", "f70578563412aabbccdd", 10},
+		{"not r/m32 (0xF7 /2, no immediate)", "f7d0", 2},
+		{"SIB byte plus disp32 (mod=10)", "018414aabbccdd", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.bytes)
+			if err != nil {
+				t.Fatalf("bad test fixture: %v", err)
+			}
+
+			_, length, err := X86{}.Decode(b, 0)
+			if err != nil {
+				t.Fatalf("Decode returned error: %v", err)
+			}
+			if length != tt.wantLength {
+				t.Errorf("Decode length = %d, want %d", length, tt.wantLength)
+			}
+		})
+	}
+}
+
+func TestX86DecodeTruncated(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes string
+	}{
+		{"truncated ModR/M byte", "01"},
+		{"truncated immediate", "05780000"},
+		{"truncated two-byte opcode", "0f"},
+		{"truncated REX + opcode", "48"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.bytes)
+			if err != nil {
+				t.Fatalf("bad test fixture: %v", err)
+			}
+
+			if _, _, err := (X86{}).Decode(b, 0); err == nil {
+				t.Errorf("Decode(%q) = nil error, want truncation error", tt.bytes)
+			}
+		})
+	}
+}